@@ -3,54 +3,54 @@
 package env
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
-	"reflect"
-	"strconv"
+	"regexp"
 	"strings"
 )
 
-// Load sets environment variables defined in the .env file.
-// Errors are only generated if the file exists.
-func Load() error {
-	file, err := os.Open(".env")
-	if os.IsNotExist(err) {
-		return nil
-	}
-	if err != nil {
-		return fmt.Errorf("error opening env file: %w", err)
-	}
-	defer file.Close()
-
-	return Parse(file)
-}
-
 // Parse sets environment variables defined in the reader.
-// The format is 'FOO=bar' with '#' used for comments.
-// The format is shown bellow:
+// The format is 'FOO=bar' with '#' used for comments, and mirrors the
+// common .env idioms:
 //  # This is a comment
 //  FOO=foo
 //
 //  # Leading and trailing spaces are ignored
 //  BAR = bar
 //
-//  # Sentences are allowed
-//  BAZ=foo bar baz
+//  # export prefixes are stripped
+//  export BAZ=baz
+//
+//  # Values can be single or double quoted, which allows embedding
+//  # '#' and spaces, and (double quotes only) escape sequences
+//  QUX='foo # bar'
+//  QUUX="line one\nline two"
+//
+//  # Double-quoted values may also span multiple lines
+//  MULTI="first
+//  second"
+//
+//  # Previously defined variables (and the process environment) can be
+//  # referenced with $VAR or ${VAR} in unquoted and double-quoted values;
+//  # undefined references expand to "". Single-quoted values are literal
+//  # and are never expanded.
+//  GREETING=hello ${BAR}
+//
+// Trailing ' #...' text on an unquoted value is treated as a comment.
 func Parse(r io.Reader) error {
-	s := bufio.NewScanner(r)
-	for s.Scan() {
-		line := strings.TrimSpace(s.Text())
-		if len(line) == 0 || line[0] == '#' {
-			continue
-		}
-		pair := strings.Split(line, "=")
-		if len(pair) != 2 {
-			return fmt.Errorf("invalid variable format: %s", pair)
-		}
-		key := strings.TrimSpace(pair[0])
-		value := strings.TrimSpace(pair[1])
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("error reading env data: %w", err)
+	}
+
+	vars, err := parse(data, nil)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range vars {
 		if err := os.Setenv(key, value); err != nil {
 			return fmt.Errorf("error settings variable %s=%s: %w", key, value, err)
 		}
@@ -58,61 +58,161 @@ func Parse(r io.Reader) error {
 	return nil
 }
 
-// Unmarshal populates the fields of a struct pointed to by v with the corresponding environment variables.
-// Only variables with the 'env' struct tag are populated:
-//  struct {
-//      Foo string  `env:"FOO"`
-//      Bar int     `env:"BAR"`
-//      Baz float64 `env:"BAZ"`
-//      Qux bool    `env:"QUX"`
-//  }
-// The types supported are strings, ints, floats and bools.
-// An error is thrown is the variable doesn't exist.
-func Unmarshal(v interface{}) error {
-	p := reflect.ValueOf(v)
-	if p.Kind() != reflect.Ptr {
-		return fmt.Errorf("expected struct pointer, got %T", v)
-	}
+var expandPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
 
-	s := p.Elem()
-	if s.Kind() != reflect.Struct {
-		return fmt.Errorf("expected struct pointer, got %T", v)
+// parse reads the .env formatted data and returns the resulting variables.
+// Values are expanded against variables parsed earlier in this data, then
+// against known (typically variables from files parsed earlier in a
+// multi-file Load/Read), and finally against the process environment.
+func parse(data []byte, known map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(known))
+	for k, v := range known {
+		result[k] = v
 	}
+	line := 1
+	i, n := 0, len(data)
 
-	for i := 0; i < s.NumField(); i++ {
-		f := s.Field(i)
-		name, ok := s.Type().Field(i).Tag.Lookup("env")
-		if ok && f.CanSet() {
-			value, ok := os.LookupEnv(name)
-			if !ok {
-				return fmt.Errorf("env variable %s not set", name)
+	for {
+		// skip blank lines and whitespace between entries
+		for i < n {
+			switch data[i] {
+			case ' ', '\t', '\r':
+				i++
+				continue
+			case '\n':
+				i++
+				line++
+				continue
 			}
+			break
+		}
+		if i >= n {
+			break
+		}
 
-			switch f.Kind() {
-			case reflect.String:
-				f.SetString(value)
-			case reflect.Int:
-				i, err := strconv.Atoi(value)
-				if err != nil {
-					return fmt.Errorf("failed conversion for %s: %w", name, err)
+		if data[i] == '#' {
+			for i < n && data[i] != '\n' {
+				i++
+			}
+			continue
+		}
+
+		startLine := line
+
+		if bytes.HasPrefix(data[i:], []byte("export ")) {
+			i += len("export ")
+			for i < n && (data[i] == ' ' || data[i] == '\t') {
+				i++
+			}
+		}
+
+		keyStart := i
+		for i < n && data[i] != '=' && data[i] != '\n' {
+			i++
+		}
+		if i >= n || data[i] != '=' {
+			return nil, fmt.Errorf("line %d: invalid variable format", startLine)
+		}
+		key := strings.TrimSpace(string(data[keyStart:i]))
+		if key == "" {
+			return nil, fmt.Errorf("line %d: invalid variable format", startLine)
+		}
+		i++ // consume '='
+
+		for i < n && (data[i] == ' ' || data[i] == '\t') {
+			i++
+		}
+
+		var value string
+		literal := false
+		if i < n && (data[i] == '\'' || data[i] == '"') {
+			quote := data[i]
+			literal = quote == '\''
+			i++
+			var sb strings.Builder
+			closed := false
+			for i < n {
+				c := data[i]
+				if c == quote {
+					i++
+					closed = true
+					break
 				}
-				f.SetInt(int64(i))
-			case reflect.Float32, reflect.Float64:
-				i, err := strconv.ParseFloat(value, 64)
-				if err != nil {
-					return fmt.Errorf("failed conversion for %s: %w", name, err)
+				if c == '\n' {
+					line++
 				}
-				f.SetFloat(i)
-			case reflect.Bool:
-				i, err := strconv.ParseBool(value)
-				if err != nil {
-					return fmt.Errorf("failed conversion for %s: %w", name, err)
+				if quote == '"' && c == '\\' && i+1 < n {
+					switch data[i+1] {
+					case 'n':
+						sb.WriteByte('\n')
+						i += 2
+						continue
+					case 'r':
+						sb.WriteByte('\r')
+						i += 2
+						continue
+					case 't':
+						sb.WriteByte('\t')
+						i += 2
+						continue
+					case '"':
+						sb.WriteByte('"')
+						i += 2
+						continue
+					case '\\':
+						sb.WriteByte('\\')
+						i += 2
+						continue
+					}
 				}
-				f.SetBool(i)
-			default:
-				return fmt.Errorf("type %v not supported", f.Kind())
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated quoted value for %s", startLine, key)
 			}
+			value = sb.String()
+			for i < n && data[i] != '\n' {
+				i++
+			}
+		} else {
+			valStart := i
+			for i < n && data[i] != '\n' {
+				i++
+			}
+			raw := string(data[valStart:i])
+			if idx := strings.Index(raw, " #"); idx >= 0 {
+				raw = raw[:idx]
+			}
+			value = strings.TrimSpace(raw)
+		}
+
+		if literal {
+			result[key] = value
+		} else {
+			result[key] = expand(value, result)
 		}
 	}
-	return nil
+
+	return result, nil
+}
+
+// expand replaces $VAR and ${VAR} references in value with the corresponding
+// entry in known, falling back to the process environment and then to the
+// empty string.
+func expand(value string, known map[string]string) string {
+	return expandPattern.ReplaceAllStringFunc(value, func(match string) string {
+		groups := expandPattern.FindStringSubmatch(match)
+		name := groups[1]
+		if name == "" {
+			name = groups[2]
+		}
+		if v, ok := known[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		return ""
+	})
 }
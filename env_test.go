@@ -2,8 +2,8 @@ package env_test
 
 import (
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -15,8 +15,22 @@ func Example() {
 	// HOST=localhost
 	// PORT=8080
 
+	dir, err := os.MkdirTemp("", "env-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte("HOST=localhost\nPORT=8080\n"), 0o600); err != nil {
+		panic(err)
+	}
+
+	os.Unsetenv("HOST")
+	os.Unsetenv("PORT")
+
 	// Errors ignored for brevity
-	env.Load()
+	env.Load(path)
 
 	config := struct {
 		Host string `env:"HOST"`
@@ -31,22 +45,6 @@ func Example() {
 	// PORT: 8080
 }
 
-func TestLoad(t *testing.T) {
-	if err := env.Load(); err != nil {
-		log.Fatal(err)
-	}
-
-	host := os.Getenv("HOST")
-	if host != "localhost" {
-		t.Fatalf("HOST: expected localhost, got %s", host)
-	}
-
-	port := os.Getenv("PORT")
-	if port != "8080" {
-		t.Fatalf("PORT: expected 8080, got %s", port)
-	}
-}
-
 func TestParse(t *testing.T) {
 	tt := []struct {
 		Name  string
@@ -101,6 +99,75 @@ func TestParse(t *testing.T) {
 				"FOO": "foo bar",
 			},
 		},
+		{
+			Name:  "Exported variable",
+			Input: `export FOO=foo`,
+			Vars: map[string]string{
+				"FOO": "foo",
+			},
+		},
+		{
+			Name:  "Inline comment",
+			Input: `FOO=foo # trailing comment`,
+			Vars: map[string]string{
+				"FOO": "foo",
+			},
+		},
+		{
+			Name:  "Hash without leading space is kept",
+			Input: `FOO=foo#bar`,
+			Vars: map[string]string{
+				"FOO": "foo#bar",
+			},
+		},
+		{
+			Name:  "Single quoted value",
+			Input: `FOO='foo # not a comment'`,
+			Vars: map[string]string{
+				"FOO": "foo # not a comment",
+			},
+		},
+		{
+			Name:  "Single quoted value is not expanded",
+			Input: `FOO='$BAR and ${BAR} are literal'`,
+			Vars: map[string]string{
+				"FOO": "$BAR and ${BAR} are literal",
+			},
+		},
+		{
+			Name:  "Double quoted value with escapes",
+			Input: `FOO="foo\nbar\t\"baz\"\\"`,
+			Vars: map[string]string{
+				"FOO": "foo\nbar\t\"baz\"\\",
+			},
+		},
+		{
+			Name: "Multi-line quoted value",
+			Input: "FOO=\"first\nsecond\"",
+			Vars: map[string]string{
+				"FOO": "first\nsecond",
+			},
+		},
+		{
+			Name: "Variable substitution",
+			Input: `
+				BAR=bar
+				FOO=${BAR}/baz
+				BAZ=$BAR
+			`,
+			Vars: map[string]string{
+				"BAR": "bar",
+				"FOO": "bar/baz",
+				"BAZ": "bar",
+			},
+		},
+		{
+			Name:  "Undefined substitution expands to empty string",
+			Input: `FOO=${UNDEFINED_VAR}bar`,
+			Vars: map[string]string{
+				"FOO": "bar",
+			},
+		},
 	}
 
 	for _, tc := range tt {
@@ -124,39 +191,14 @@ func TestParse(t *testing.T) {
 	}
 }
 
-func TestUnmarshal(t *testing.T) {
-	t.Run("Valid struct", func(t *testing.T) {
-		os.Setenv("STRING", "foo")
-		os.Setenv("INTEGER", "5")
-		os.Setenv("FLOAT_32", "51.432434")
-		os.Setenv("FLOAT_64", "51.43243344285539")
-		os.Setenv("BOOLEAN", "true")
-
-		s := struct {
-			String  string  `env:"STRING"`
-			Integer int     `env:"INTEGER"`
-			Float32 float32 `env:"FLOAT_32"`
-			Float64 float32 `env:"FLOAT_64"`
-			Boolean bool    `env:"BOOLEAN"`
-		}{}
-		if err := env.Unmarshal(&s); err != nil {
-			t.Fatalf("failed to unmarshal: %v", err)
-		}
-
-		if s.String != "foo" {
-			t.Errorf("STRING: expected foo, got %v", s.String)
-		}
-		if s.Integer != 5 {
-			t.Errorf("INTEGER: expected 5, got %v", s.Integer)
-		}
-		if s.Float32 != 51.432434 {
-			t.Errorf("FLOAT_32: expected 51.432434, got %v", s.Float32)
-		}
-		if s.Float64 != 51.43243344285539 {
-			t.Errorf("FLOAT_64: expected 51.43243344285539, got %v", s.Float64)
-		}
-		if s.Boolean != true {
-			t.Errorf("BOOLEAN: expected true, got %v", s.Boolean)
-		}
-	})
+func TestParseInvalid(t *testing.T) {
+	r := strings.NewReader("FOO=foo\nBAR\nBAZ=baz")
+	err := env.Parse(r)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error to mention line 2, got %v", err)
+	}
 }
+
@@ -0,0 +1,76 @@
+package env
+
+import (
+	"fmt"
+	"os"
+)
+
+// Load sets environment variables defined in the given files, defaulting to
+// ".env" when none are given. Later files take precedence over earlier
+// ones, but variables already present in the process environment are left
+// untouched; see Overload to replace them instead. Errors are only
+// generated if a named file exists but cannot be read or parsed.
+func Load(filenames ...string) error {
+	vars, err := Read(filenames...)
+	if err != nil {
+		return err
+	}
+	return applyVars(vars, false)
+}
+
+// Overload behaves like Load, except that variables already present in the
+// process environment are overwritten by the files.
+func Overload(filenames ...string) error {
+	vars, err := Read(filenames...)
+	if err != nil {
+		return err
+	}
+	return applyVars(vars, true)
+}
+
+// Read parses the given files, defaulting to ".env" when none are given,
+// and returns the resulting variables without touching the process
+// environment. Later files take precedence over earlier ones. Errors are
+// only generated if a named file exists but cannot be read or parsed.
+func Read(filenames ...string) (map[string]string, error) {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+
+	result := map[string]string{}
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error opening env file: %w", err)
+		}
+
+		vars, err := parse(data, result)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range vars {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+// applyVars sets each variable in the process environment. If overwrite is
+// false, variables already present in the process environment are left
+// untouched.
+func applyVars(vars map[string]string, overwrite bool) error {
+	for key, value := range vars {
+		if !overwrite {
+			if _, ok := os.LookupEnv(key); ok {
+				continue
+			}
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("error settings variable %s=%s: %w", key, value, err)
+		}
+	}
+	return nil
+}
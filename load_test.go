@@ -0,0 +1,111 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GeorgeNewby/env"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, ".env", "HOST=localhost\nPORT=8080\n")
+
+	os.Unsetenv("HOST")
+	os.Unsetenv("PORT")
+
+	if err := env.Load(path); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+
+	host := os.Getenv("HOST")
+	if host != "localhost" {
+		t.Fatalf("HOST: expected localhost, got %s", host)
+	}
+
+	port := os.Getenv("PORT")
+	if port != "8080" {
+		t.Fatalf("PORT: expected 8080, got %s", port)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestLoadLayering(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, ".env", "FOO=base\nBAR=base\n")
+	override := writeFile(t, dir, ".env.local", "FOO=local\n")
+
+	os.Unsetenv("FOO")
+	os.Unsetenv("BAR")
+
+	if err := env.Load(base, override); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+
+	if v := os.Getenv("FOO"); v != "local" {
+		t.Errorf("FOO: expected local, got %s", v)
+	}
+	if v := os.Getenv("BAR"); v != "base" {
+		t.Errorf("BAR: expected base, got %s", v)
+	}
+}
+
+func TestLoadDoesNotOverwriteExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, ".env", "EXISTING=from-file\n")
+
+	os.Setenv("EXISTING", "from-process")
+	if err := env.Load(path); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+
+	if v := os.Getenv("EXISTING"); v != "from-process" {
+		t.Errorf("EXISTING: expected from-process, got %s", v)
+	}
+}
+
+func TestOverloadOverwritesExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, ".env", "EXISTING=from-file\n")
+
+	os.Setenv("EXISTING", "from-process")
+	if err := env.Overload(path); err != nil {
+		t.Fatalf("failed to overload: %v", err)
+	}
+
+	if v := os.Getenv("EXISTING"); v != "from-file" {
+		t.Errorf("EXISTING: expected from-file, got %s", v)
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, ".env", "FOO=foo\n")
+
+	os.Unsetenv("FOO")
+	vars, err := env.Read(path)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if vars["FOO"] != "foo" {
+		t.Errorf("FOO: expected foo, got %s", vars["FOO"])
+	}
+	if _, ok := os.LookupEnv("FOO"); ok {
+		t.Error("Read must not set process environment variables")
+	}
+}
+
+func TestLoadMissingFileIsNotAnError(t *testing.T) {
+	if err := env.Load(filepath.Join(t.TempDir(), "does-not-exist.env")); err != nil {
+		t.Fatalf("expected no error for a missing file, got %v", err)
+	}
+}
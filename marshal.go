@@ -0,0 +1,223 @@
+package env
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Marshal serializes the fields of v, a struct or a pointer to one, to .env
+// format. Only fields with an 'env' struct tag are emitted, in declaration
+// order:
+//  struct {
+//      Host string `env:"HOST"`
+//      Port int    `env:"PORT,comment=the port to listen on"`
+//  }
+// A field tagged with the 'comment' option emits a '# ...' line immediately
+// above it. Values containing spaces, tabs, '#', '"', '\', or newlines are
+// double-quoted, with '\', '"' and newlines escaped; a single quote alone
+// does not cause quoting.
+//
+// Marshal supports the same field types as Unmarshal: strings, ints,
+// floats, bools, time.Duration, url.URL, slices, map[string]string, and any
+// type implementing encoding.TextMarshaler. A slice is joined with ',' or
+// the tag's 'separator' option. A field whose type is a struct, or a
+// pointer to one, is recursed into, with its 'envPrefix' tag (if any)
+// prepended to every child name, mirroring Unmarshal's nested struct
+// support.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := MarshalTo(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalTo writes v to w in the same format as Marshal.
+func MarshalTo(w io.Writer, v interface{}) error {
+	s := reflect.ValueOf(v)
+	for s.Kind() == reflect.Ptr {
+		s = s.Elem()
+	}
+	if s.Kind() != reflect.Struct {
+		return fmt.Errorf("expected struct or struct pointer, got %T", v)
+	}
+
+	return marshalStruct(w, s, "")
+}
+
+func marshalStruct(w io.Writer, s reflect.Value, prefix string) error {
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		sf := s.Type().Field(i)
+
+		if marshalIsNestedStruct(f.Type()) {
+			for f.Kind() == reflect.Ptr {
+				if f.IsNil() {
+					break
+				}
+				f = f.Elem()
+			}
+			if f.Kind() != reflect.Struct {
+				continue
+			}
+			if err := marshalStruct(w, f, prefix+sf.Tag.Get("envPrefix")); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := sf.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		t := parseTag(raw)
+		if t.Name == "" {
+			continue
+		}
+		name := prefix + t.Name
+
+		if comment, ok := t.Options["comment"]; ok {
+			if _, err := fmt.Fprintf(w, "# %s\n", comment); err != nil {
+				return err
+			}
+		}
+
+		separator := t.Options["separator"]
+		if separator == "" {
+			separator = ","
+		}
+
+		value, err := marshalValue(f, separator)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, quoteIfNeeded(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalIsNestedStruct mirrors isNestedStruct, except it also treats a
+// type implementing encoding.TextMarshaler as a leaf value rather than
+// recursing into it: unlike Unmarshal, Marshal must support TextMarshaler
+// types whose receiver has no matching UnmarshalText method.
+func marshalIsNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == urlType {
+		return false
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+	return !t.Implements(textMarshalerType) && !reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+func marshalValue(f reflect.Value, separator string) (string, error) {
+	switch {
+	case f.Type() == durationType:
+		return f.Interface().(time.Duration).String(), nil
+	case f.Type() == urlType:
+		u := f.Interface().(url.URL)
+		return u.String(), nil
+	case f.Type().Implements(textMarshalerType):
+		text, err := f.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	case f.CanAddr() && f.Addr().Type().Implements(textMarshalerType):
+		text, err := f.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		return f.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(f.Int(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(f.Float(), 'f', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(f.Bool()), nil
+	case reflect.Slice:
+		return marshalSlice(f, separator)
+	case reflect.Map:
+		return marshalMap(f)
+	default:
+		return "", fmt.Errorf("type %v not supported", f.Kind())
+	}
+}
+
+func marshalSlice(f reflect.Value, separator string) (string, error) {
+	parts := make([]string, f.Len())
+	for i := 0; i < f.Len(); i++ {
+		part, err := marshalValue(f.Index(i), separator)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, separator), nil
+}
+
+func marshalMap(f reflect.Value) (string, error) {
+	if f.Type().Key().Kind() != reflect.String || f.Type().Elem().Kind() != reflect.String {
+		return "", fmt.Errorf("type %v not supported", f.Type())
+	}
+
+	keys := make([]string, 0, f.Len())
+	iter := f.MapRange()
+	for iter.Next() {
+		keys = append(keys, iter.Key().String())
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + ":" + f.MapIndex(reflect.ValueOf(key)).String()
+	}
+	return strings.Join(pairs, ","), nil
+}
+
+// quoteIfNeeded double-quotes value, escaping '\\', '"' and newlines, if it
+// contains a space, tab, '#', '"', '\\', or newline; otherwise it is
+// returned as-is.
+func quoteIfNeeded(value string) string {
+	if !strings.ContainsAny(value, " \t#\n\"\\") {
+		return value
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\n':
+			sb.WriteString(`\n`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,145 @@
+package env_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GeorgeNewby/env"
+)
+
+func TestMarshal(t *testing.T) {
+	s := struct {
+		Host    string `env:"HOST"`
+		Port    int    `env:"PORT,comment=the port to listen on"`
+		Message string `env:"MESSAGE"`
+	}{
+		Host:    "localhost",
+		Port:    8080,
+		Message: "hello # world",
+	}
+
+	out, err := env.Marshal(&s)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	want := "HOST=localhost\n# the port to listen on\nPORT=8080\nMESSAGE=\"hello # world\"\n"
+	if string(out) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, out)
+	}
+}
+
+func TestMarshalTo(t *testing.T) {
+	s := struct {
+		Name string `env:"NAME"`
+	}{Name: "multi\nline"}
+
+	var sb strings.Builder
+	if err := env.MarshalTo(&sb, s); err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	want := "NAME=\"multi\\nline\"\n"
+	if sb.String() != want {
+		t.Errorf("expected %q, got %q", want, sb.String())
+	}
+}
+
+func TestMarshalDuration(t *testing.T) {
+	s := struct {
+		Timeout time.Duration `env:"TIMEOUT"`
+	}{Timeout: 5 * time.Second}
+
+	out, err := env.Marshal(&s)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	want := "TIMEOUT=5s\n"
+	if string(out) != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+}
+
+func TestMarshalNestedStruct(t *testing.T) {
+	s := struct {
+		Name string `env:"NAME"`
+		DB   struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT"`
+		} `envPrefix:"DB_"`
+	}{Name: "myapp"}
+	s.DB.Host = "db.internal"
+	s.DB.Port = 5432
+
+	out, err := env.Marshal(&s)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	want := "NAME=myapp\nDB_HOST=db.internal\nDB_PORT=5432\n"
+	if string(out) != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+}
+
+// writeOnly implements encoding.TextMarshaler only, with a value receiver,
+// to exercise fields that Marshal must support but Unmarshal cannot.
+type writeOnly struct {
+	name string
+}
+
+func (w writeOnly) MarshalText() ([]byte, error) {
+	return []byte(w.name), nil
+}
+
+func TestMarshalTextMarshalerOnly(t *testing.T) {
+	s := struct {
+		Level writeOnly `env:"LEVEL"`
+	}{Level: writeOnly{name: "warn"}}
+
+	out, err := env.Marshal(&s)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	want := "LEVEL=warn\n"
+	if string(out) != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+}
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	type config struct {
+		Name string `env:"NAME"`
+		DB   struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT"`
+		} `envPrefix:"DB_"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Tags    []string      `env:"TAGS"`
+	}
+
+	in := config{Name: "myapp", Timeout: 30 * time.Second, Tags: []string{"a", "b"}}
+	in.DB.Host = "db.internal"
+	in.DB.Port = 5432
+
+	out, err := env.Marshal(&in)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var got config
+	if err := env.Parse(strings.NewReader(string(out))); err != nil {
+		t.Fatalf("failed to parse marshaled output: %v", err)
+	}
+	if err := env.Unmarshal(&got); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, in) {
+		t.Errorf("round-trip mismatch: expected %+v, got %+v", in, got)
+	}
+}
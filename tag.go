@@ -0,0 +1,74 @@
+package env
+
+import "strings"
+
+// tag holds the parsed components of an `env:"..."` struct tag: the
+// variable name and any comma-separated options that follow it.
+type tag struct {
+	Name    string
+	Options map[string]string
+}
+
+// tagOptionNames are the recognized option keys that may follow an env
+// tag's name. After a key=value option, only a token matching one of these
+// (optionally followed by "=...") starts a new option; any other token is
+// folded into that option's value, so that a value such as default=a,b,c
+// survives intact instead of being truncated at the first embedded comma.
+// A token after a bare flag, or the first token, always starts an option.
+var tagOptionNames = map[string]bool{
+	"default":   true,
+	"required":  true,
+	"expand":    true,
+	"separator": true,
+	"comment":   true,
+}
+
+// parseTag splits a raw `env` tag value into its name and its options.
+// Options may be bare flags (e.g. "required") or key=value pairs (e.g.
+// "default=8080"); bare flags are stored with an empty value.
+func parseTag(raw string) tag {
+	nameEnd := strings.IndexByte(raw, ',')
+	if nameEnd < 0 {
+		return tag{Name: raw, Options: map[string]string{}}
+	}
+
+	t := tag{Name: raw[:nameEnd], Options: map[string]string{}}
+	tokens := strings.Split(raw[nameEnd+1:], ",")
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "" {
+			continue
+		}
+
+		key, value, hasValue := token, "", false
+		if idx := strings.IndexByte(token, '='); idx >= 0 {
+			key, value, hasValue = token[:idx], token[idx+1:], true
+		}
+
+		// A key=value option absorbs any following tokens that don't
+		// themselves start a recognized option, since those commas were
+		// part of the value rather than separators between options.
+		for hasValue && i+1 < len(tokens) && !tagOptionNames[optionKey(tokens[i+1])] {
+			i++
+			value += "," + tokens[i]
+		}
+
+		t.Options[key] = value
+	}
+	return t
+}
+
+// optionKey returns the key portion of a raw "key" or "key=value" token.
+func optionKey(token string) string {
+	if idx := strings.IndexByte(token, '='); idx >= 0 {
+		return token[:idx]
+	}
+	return token
+}
+
+// Has reports whether the option was present on the tag, bare or with a value.
+func (t tag) Has(name string) bool {
+	_, ok := t.Options[name]
+	return ok
+}
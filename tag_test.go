@@ -0,0 +1,59 @@
+package env
+
+import "testing"
+
+func TestParseTag(t *testing.T) {
+	tt := []struct {
+		Name string
+		Raw  string
+		Want tag
+	}{
+		{
+			Name: "Name only",
+			Raw:  "PORT",
+			Want: tag{Name: "PORT", Options: map[string]string{}},
+		},
+		{
+			Name: "Bare flag",
+			Raw:  "NAME,required",
+			Want: tag{Name: "NAME", Options: map[string]string{"required": ""}},
+		},
+		{
+			Name: "Key value option",
+			Raw:  "PORT,default=8080",
+			Want: tag{Name: "PORT", Options: map[string]string{"default": "8080"}},
+		},
+		{
+			Name: "Value containing commas is preserved",
+			Raw:  "LIST,default=a,b,c",
+			Want: tag{Name: "LIST", Options: map[string]string{"default": "a,b,c"}},
+		},
+		{
+			Name: "Comma-bearing value followed by another option",
+			Raw:  "LIST,default=a,b,c,required",
+			Want: tag{Name: "LIST", Options: map[string]string{"default": "a,b,c", "required": ""}},
+		},
+		{
+			Name: "Multiple options in any order",
+			Raw:  "HOSTS,separator=;,required",
+			Want: tag{Name: "HOSTS", Options: map[string]string{"separator": ";", "required": ""}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			got := parseTag(tc.Raw)
+			if got.Name != tc.Want.Name {
+				t.Errorf("Name: expected %q, got %q", tc.Want.Name, got.Name)
+			}
+			if len(got.Options) != len(tc.Want.Options) {
+				t.Fatalf("Options: expected %v, got %v", tc.Want.Options, got.Options)
+			}
+			for k, v := range tc.Want.Options {
+				if got.Options[k] != v {
+					t.Errorf("Options[%s]: expected %q, got %q", k, v, got.Options[k])
+				}
+			}
+		})
+	}
+}
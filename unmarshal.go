@@ -0,0 +1,280 @@
+package env
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options configures the behavior of UnmarshalWithOptions.
+type Options struct {
+	// Prefix is prepended to every field's env tag name before it is
+	// looked up, so that structs sharing a common namespace can be
+	// composed without repeating the prefix on every tag.
+	Prefix string
+}
+
+// Unmarshal populates the fields of a struct pointed to by v with the
+// corresponding environment variables. Only fields with an 'env' struct tag
+// are populated:
+//  struct {
+//      Host string `env:"HOST"`
+//      Port int    `env:"PORT,default=8080"`
+//      Name string `env:"NAME,required"`
+//  }
+// The tag name may be followed by comma-separated options:
+//  default=VALUE  used when the variable is not set
+//  required       Unmarshal fails if the variable is not set
+//  expand         $VAR/${VAR} references in the value are expanded against
+//                 the process environment before conversion
+//  separator=SEP  the delimiter used to split slice values (default ",")
+// Fields for which the variable is unset and that have neither 'default'
+// nor 'required' are left unchanged.
+//
+// Supported field types are strings, ints, floats, bools, time.Duration,
+// url.URL, slices and map[string]string, and any type implementing
+// encoding.TextUnmarshaler.
+//
+// A field whose type is a struct, or a pointer to one, is recursed into
+// rather than converted; an 'envPrefix' tag on that field is prepended to
+// every name looked up for its children:
+//  struct {
+//      DB struct {
+//          Host string `env:"HOST"`
+//          Port int    `env:"PORT"`
+//      } `envPrefix:"DB_"`
+//  }
+// populates DB.Host and DB.Port from DB_HOST and DB_PORT. This is a common
+// way to group related configuration, such as database, redis, or SMTP
+// settings, under one namespace.
+func Unmarshal(v interface{}) error {
+	return UnmarshalWithOptions(v, Options{})
+}
+
+// UnmarshalWithOptions behaves like Unmarshal but accepts Options to
+// customize the lookup, such as a Prefix applied to every tag name.
+func UnmarshalWithOptions(v interface{}, opts Options) error {
+	p := reflect.ValueOf(v)
+	if p.Kind() != reflect.Ptr {
+		return fmt.Errorf("expected struct pointer, got %T", v)
+	}
+
+	s := p.Elem()
+	if s.Kind() != reflect.Struct {
+		return fmt.Errorf("expected struct pointer, got %T", v)
+	}
+
+	_, err := unmarshalStruct(s, opts.Prefix, map[reflect.Type]bool{})
+	return err
+}
+
+// unmarshalStruct populates s's fields and reports whether any of them (or
+// their nested descendants) were actually set from an environment variable
+// or a default. seen tracks the struct types on the current recursion path
+// so that self- or mutually-referential nested structs are rejected with an
+// error instead of recursing forever.
+func unmarshalStruct(s reflect.Value, prefix string, seen map[reflect.Type]bool) (bool, error) {
+	structType := s.Type()
+	if seen[structType] {
+		return false, fmt.Errorf("env: cyclic nested struct %s", structType)
+	}
+	seen[structType] = true
+	defer delete(seen, structType)
+
+	set := false
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		sf := s.Type().Field(i)
+
+		if isNestedStruct(f.Type()) {
+			var (
+				nestedSet bool
+				err       error
+			)
+			if f.Kind() == reflect.Ptr {
+				if !f.CanSet() {
+					continue
+				}
+				nestedSet, err = unmarshalNestedPointer(f, prefix+sf.Tag.Get("envPrefix"), seen)
+			} else {
+				nestedSet, err = unmarshalStruct(f, prefix+sf.Tag.Get("envPrefix"), seen)
+			}
+			if err != nil {
+				return set, err
+			}
+			if nestedSet {
+				set = true
+			}
+			continue
+		}
+
+		raw, ok := sf.Tag.Lookup("env")
+		if !ok || !f.CanSet() {
+			continue
+		}
+
+		t := parseTag(raw)
+		if t.Name == "" {
+			continue
+		}
+		name := prefix + t.Name
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			if def, hasDefault := t.Options["default"]; hasDefault {
+				value = def
+			} else if t.Has("required") {
+				return set, fmt.Errorf("env variable %s not set", name)
+			} else {
+				continue
+			}
+		}
+
+		if t.Has("expand") {
+			value = os.Expand(value, os.Getenv)
+		}
+
+		separator := t.Options["separator"]
+		if separator == "" {
+			separator = ","
+		}
+
+		if err := setField(f, value, separator); err != nil {
+			return set, fmt.Errorf("failed conversion for %s: %w", name, err)
+		}
+		set = true
+	}
+	return set, nil
+}
+
+// unmarshalNestedPointer populates a pointer-to-struct field, allocating it
+// only if at least one of its children was actually set from an
+// environment variable or a default; an already-set pointer is always
+// populated in place.
+func unmarshalNestedPointer(f reflect.Value, prefix string, seen map[reflect.Type]bool) (bool, error) {
+	if !f.IsNil() {
+		return unmarshalStruct(f.Elem(), prefix, seen)
+	}
+
+	target := reflect.New(f.Type().Elem())
+	set, err := unmarshalStruct(target.Elem(), prefix, seen)
+	if err != nil {
+		return false, err
+	}
+	if set {
+		f.Set(target)
+	}
+	return set, nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isNestedStruct reports whether t is a struct, or a pointer to one, that
+// should be recursed into rather than converted directly: this excludes
+// special-cased struct types (url.URL) and anything implementing
+// encoding.TextUnmarshaler.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == urlType {
+		return false
+	}
+	return !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+func setField(f reflect.Value, value, separator string) error {
+	switch {
+	case f.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	case f.Type() == urlType:
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(*u))
+		return nil
+	case f.CanAddr() && f.Addr().Type().Implements(textUnmarshalerType):
+		return f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(fl)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Slice:
+		return setSlice(f, value, separator)
+	case reflect.Map:
+		return setMap(f, value)
+	default:
+		return fmt.Errorf("type %v not supported", f.Kind())
+	}
+	return nil
+}
+
+func setSlice(f reflect.Value, value, separator string) error {
+	if value == "" {
+		f.Set(reflect.MakeSlice(f.Type(), 0, 0))
+		return nil
+	}
+
+	parts := strings.Split(value, separator)
+	s := reflect.MakeSlice(f.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		if err := setField(s.Index(i), strings.TrimSpace(part), separator); err != nil {
+			return err
+		}
+	}
+	f.Set(s)
+	return nil
+}
+
+func setMap(f reflect.Value, value string) error {
+	if f.Type().Key().Kind() != reflect.String || f.Type().Elem().Kind() != reflect.String {
+		return fmt.Errorf("type %v not supported", f.Type())
+	}
+
+	m := reflect.MakeMap(f.Type())
+	if value != "" {
+		for _, pair := range strings.Split(value, ",") {
+			kv := strings.SplitN(pair, ":", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid map entry %q", pair)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(kv[0])), reflect.ValueOf(strings.TrimSpace(kv[1])))
+		}
+	}
+	f.Set(m)
+	return nil
+}
@@ -0,0 +1,303 @@
+package env_test
+
+import (
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GeorgeNewby/env"
+)
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("Valid struct", func(t *testing.T) {
+		os.Setenv("STRING", "foo")
+		os.Setenv("INTEGER", "5")
+		os.Setenv("FLOAT_32", "51.432434")
+		os.Setenv("FLOAT_64", "51.43243344285539")
+		os.Setenv("BOOLEAN", "true")
+
+		s := struct {
+			String  string  `env:"STRING"`
+			Integer int     `env:"INTEGER"`
+			Float32 float32 `env:"FLOAT_32"`
+			Float64 float32 `env:"FLOAT_64"`
+			Boolean bool    `env:"BOOLEAN"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+
+		if s.String != "foo" {
+			t.Errorf("STRING: expected foo, got %v", s.String)
+		}
+		if s.Integer != 5 {
+			t.Errorf("INTEGER: expected 5, got %v", s.Integer)
+		}
+		if s.Float32 != 51.432434 {
+			t.Errorf("FLOAT_32: expected 51.432434, got %v", s.Float32)
+		}
+		if s.Float64 != 51.43243344285539 {
+			t.Errorf("FLOAT_64: expected 51.43243344285539, got %v", s.Float64)
+		}
+		if s.Boolean != true {
+			t.Errorf("BOOLEAN: expected true, got %v", s.Boolean)
+		}
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		os.Unsetenv("NOT_SET")
+		s := struct {
+			Port int `env:"NOT_SET,default=8080"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if s.Port != 8080 {
+			t.Errorf("Port: expected 8080, got %v", s.Port)
+		}
+	})
+
+	t.Run("Required", func(t *testing.T) {
+		os.Unsetenv("MISSING_REQUIRED")
+		s := struct {
+			Name string `env:"MISSING_REQUIRED,required"`
+		}{}
+		if err := env.Unmarshal(&s); err == nil {
+			t.Fatal("expected an error for missing required variable")
+		}
+	})
+
+	t.Run("Missing optional is left unchanged", func(t *testing.T) {
+		os.Unsetenv("MISSING_OPTIONAL")
+		s := struct {
+			Name string `env:"MISSING_OPTIONAL"`
+		}{Name: "unchanged"}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if s.Name != "unchanged" {
+			t.Errorf("Name: expected unchanged, got %v", s.Name)
+		}
+	})
+
+	t.Run("Expand", func(t *testing.T) {
+		os.Setenv("EXPAND_BASE", "world")
+		os.Setenv("EXPAND_VALUE", "hello ${EXPAND_BASE}")
+		s := struct {
+			Value string `env:"EXPAND_VALUE,expand"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if s.Value != "hello world" {
+			t.Errorf("Value: expected %q, got %q", "hello world", s.Value)
+		}
+	})
+
+	t.Run("Slice with default separator", func(t *testing.T) {
+		os.Setenv("HOSTS", "a,b,c")
+		s := struct {
+			Hosts []string `env:"HOSTS"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if len(s.Hosts) != 3 || s.Hosts[0] != "a" || s.Hosts[1] != "b" || s.Hosts[2] != "c" {
+			t.Errorf("Hosts: expected [a b c], got %v", s.Hosts)
+		}
+	})
+
+	t.Run("Slice with custom separator", func(t *testing.T) {
+		os.Setenv("HOSTS_SEMI", "a;b;c")
+		s := struct {
+			Hosts []string `env:"HOSTS_SEMI,separator=;"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if len(s.Hosts) != 3 || s.Hosts[2] != "c" {
+			t.Errorf("Hosts: expected [a b c], got %v", s.Hosts)
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		os.Setenv("LABELS", "K1:V1,K2:V2")
+		s := struct {
+			Labels map[string]string `env:"LABELS"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if s.Labels["K1"] != "V1" || s.Labels["K2"] != "V2" {
+			t.Errorf("Labels: expected K1:V1,K2:V2, got %v", s.Labels)
+		}
+	})
+
+	t.Run("time.Duration", func(t *testing.T) {
+		os.Setenv("TIMEOUT", "5s")
+		s := struct {
+			Timeout time.Duration `env:"TIMEOUT"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if s.Timeout != 5*time.Second {
+			t.Errorf("Timeout: expected 5s, got %v", s.Timeout)
+		}
+	})
+
+	t.Run("url.URL", func(t *testing.T) {
+		os.Setenv("ENDPOINT", "https://example.com/path")
+		s := struct {
+			Endpoint url.URL `env:"ENDPOINT"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if s.Endpoint.Host != "example.com" || s.Endpoint.Path != "/path" {
+			t.Errorf("Endpoint: unexpected value %v", s.Endpoint)
+		}
+	})
+
+	t.Run("encoding.TextUnmarshaler", func(t *testing.T) {
+		os.Setenv("LEVEL", "warn")
+		s := struct {
+			Level logLevel `env:"LEVEL"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if s.Level.name != "warn" {
+			t.Errorf("Level: expected warn, got %v", s.Level.name)
+		}
+	})
+
+	t.Run("slice of encoding.TextUnmarshaler", func(t *testing.T) {
+		os.Setenv("LEVELS", "warn,error")
+		s := struct {
+			Levels []logLevel `env:"LEVELS"`
+		}{}
+		if err := env.Unmarshal(&s); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if len(s.Levels) != 2 || s.Levels[0].name != "warn" || s.Levels[1].name != "error" {
+			t.Errorf("Levels: unexpected value %v", s.Levels)
+		}
+	})
+}
+
+// logLevel is a minimal encoding.TextUnmarshaler used to exercise the
+// custom-type conversion path in setField.
+type logLevel struct {
+	name string
+}
+
+func (l *logLevel) UnmarshalText(text []byte) error {
+	l.name = string(text)
+	return nil
+}
+
+func TestUnmarshalNestedStruct(t *testing.T) {
+	os.Setenv("DB_HOST", "db.internal")
+	os.Setenv("DB_PORT", "5432")
+	os.Setenv("NAME", "myapp")
+
+	s := struct {
+		Name string `env:"NAME"`
+		DB   struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT"`
+		} `envPrefix:"DB_"`
+	}{}
+	if err := env.Unmarshal(&s); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if s.Name != "myapp" {
+		t.Errorf("Name: expected myapp, got %v", s.Name)
+	}
+	if s.DB.Host != "db.internal" {
+		t.Errorf("DB.Host: expected db.internal, got %v", s.DB.Host)
+	}
+	if s.DB.Port != 5432 {
+		t.Errorf("DB.Port: expected 5432, got %v", s.DB.Port)
+	}
+}
+
+func TestUnmarshalNestedPointerStruct(t *testing.T) {
+	os.Setenv("REDIS_HOST", "redis.internal")
+
+	s := struct {
+		Redis *struct {
+			Host string `env:"HOST"`
+		} `envPrefix:"REDIS_"`
+	}{}
+	if err := env.Unmarshal(&s); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if s.Redis == nil {
+		t.Fatal("Redis: expected a non-nil pointer")
+	}
+	if s.Redis.Host != "redis.internal" {
+		t.Errorf("Redis.Host: expected redis.internal, got %v", s.Redis.Host)
+	}
+}
+
+func TestUnmarshalNestedPointerStructLeftNilWhenUnset(t *testing.T) {
+	os.Unsetenv("CACHE_HOST")
+
+	s := struct {
+		Cache *struct {
+			Host string `env:"HOST"`
+		} `envPrefix:"CACHE_"`
+	}{}
+	if err := env.Unmarshal(&s); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if s.Cache != nil {
+		t.Errorf("Cache: expected nil, got %+v", s.Cache)
+	}
+}
+
+func TestUnmarshalNestedPointerStructAllocatedForExplicitZeroValue(t *testing.T) {
+	os.Setenv("CACHE_HOST", "")
+
+	s := struct {
+		Cache *struct {
+			Host string `env:"HOST"`
+		} `envPrefix:"CACHE_"`
+	}{}
+	if err := env.Unmarshal(&s); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if s.Cache == nil {
+		t.Fatal("Cache: expected a non-nil pointer, an explicit empty value was set")
+	}
+}
+
+type selfReferentialNode struct {
+	Next *selfReferentialNode `envPrefix:"NEXT_"`
+}
+
+func TestUnmarshalCyclicNestedStruct(t *testing.T) {
+	if err := env.Unmarshal(&selfReferentialNode{}); err == nil {
+		t.Fatal("expected an error for a self-referential nested struct")
+	}
+}
+
+func TestUnmarshalWithOptions(t *testing.T) {
+	os.Setenv("APP_NAME", "myapp")
+	s := struct {
+		Name string `env:"NAME"`
+	}{}
+	if err := env.UnmarshalWithOptions(&s, env.Options{Prefix: "APP_"}); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if s.Name != "myapp" {
+		t.Errorf("Name: expected myapp, got %v", s.Name)
+	}
+}